@@ -0,0 +1,232 @@
+package main
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+	"golang.org/x/crypto/bcrypt"
+)
+
+// sessionKeyPrefix namespaces session tokens in Redis, separate from cached users
+const sessionKeyPrefix = "session:"
+
+// Session is the data stored in Redis for a signed-in user, keyed by session token
+type Session struct {
+	UserID uint   `json:"user_id"`
+	Role   string `json:"role"`
+}
+
+// contextKey is a private type for values stored on a request context, avoiding
+// collisions with keys set by other packages
+type contextKey string
+
+const authUserContextKey contextKey = "authUser"
+
+// UserFromContext returns the authenticated user injected by AuthService.Middleware
+func UserFromContext(ctx context.Context) (*Session, bool) {
+	user, ok := ctx.Value(authUserContextKey).(*Session)
+	return user, ok
+}
+
+// AuthService handles signup/signin and session verification.
+// Sessions are opaque random tokens stored in Redis with a bounded TTL.
+// It also shares the UserCacher and UserEventBus used by UserService/
+// GRPCUserServer, since SignUp is the only user-creation path and must
+// keep the cache and other instances in sync the same way they do.
+type AuthService struct {
+	storer     UserStorer
+	sessions   *redis.Client
+	sessionTTL time.Duration
+	cacher     UserCacher
+	events     *UserEventBus
+}
+
+// NewAuthService creates a new AuthService
+func NewAuthService(storer UserStorer, sessions *redis.Client, sessionTTL time.Duration, cacher UserCacher, events *UserEventBus) *AuthService {
+	return &AuthService{storer: storer, sessions: sessions, sessionTTL: sessionTTL, cacher: cacher, events: events}
+}
+
+// signupRequest is the expected body of POST /auth/signup
+type signupRequest struct {
+	Name     string `json:"name"`
+	Email    string `json:"email"`
+	Password string `json:"password"`
+}
+
+// SignUp handles the creation of a new authenticated user with a hashed password
+func (a *AuthService) SignUp(w http.ResponseWriter, r *http.Request) {
+	log := requestLogger(r.Context())
+
+	var req signupRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, "Error: Invalid request body", http.StatusBadRequest)
+		log.Error("invalid request body", "error", err)
+		return
+	}
+	if req.Name == "" || req.Email == "" || req.Password == "" {
+		http.Error(w, "Error: Name, email and password are required", http.StatusBadRequest)
+		return
+	}
+
+	hash, err := bcrypt.GenerateFromPassword([]byte(req.Password), bcrypt.DefaultCost)
+	if err != nil {
+		http.Error(w, "Error: Failed to hash password", http.StatusInternalServerError)
+		log.Error("failed to hash password", "error", err)
+		return
+	}
+
+	user := &User{
+		Name:         req.Name,
+		Email:        req.Email,
+		PasswordHash: string(hash),
+		Role:         RoleMember,
+	}
+	if err := a.storer.CreateUser(user); err != nil {
+		http.Error(w, "Error: Failed to create user", http.StatusInternalServerError)
+		log.Error("failed to create user", "error", err)
+		return
+	}
+
+	// Overwrite any negative cache entry left behind by an earlier lookup for this ID
+	if err := a.cacher.Set(strconv.Itoa(int(user.ID)), user.Public()); err != nil {
+		log.Error("failed to store user in cache", "id", user.ID, "error", err)
+	}
+
+	if err := a.events.Publish(UserCreatedOp, user.ID, user.Name); err != nil {
+		log.Error("failed to publish user.created event", "id", user.ID, "error", err)
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusCreated)
+	json.NewEncoder(w).Encode(user.Public())
+}
+
+// signinRequest is the expected body of POST /auth/signin
+type signinRequest struct {
+	Email    string `json:"email"`
+	Password string `json:"password"`
+}
+
+// signinResponse carries the issued session token back to the client
+type signinResponse struct {
+	Token string `json:"token"`
+}
+
+// SignIn verifies credentials and issues a session token stored in Redis
+func (a *AuthService) SignIn(w http.ResponseWriter, r *http.Request) {
+	log := requestLogger(r.Context())
+
+	var req signinRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, "Error: Invalid request body", http.StatusBadRequest)
+		log.Error("invalid request body", "error", err)
+		return
+	}
+
+	user, err := a.storer.GetUserByEmail(req.Email)
+	if err != nil {
+		http.Error(w, "Error: Invalid email or password", http.StatusUnauthorized)
+		return
+	}
+
+	if err := bcrypt.CompareHashAndPassword([]byte(user.PasswordHash), []byte(req.Password)); err != nil {
+		http.Error(w, "Error: Invalid email or password", http.StatusUnauthorized)
+		return
+	}
+
+	token, err := generateToken()
+	if err != nil {
+		http.Error(w, "Error: Failed to create session", http.StatusInternalServerError)
+		log.Error("failed to generate session token", "error", err)
+		return
+	}
+
+	session := Session{UserID: user.ID, Role: user.Role}
+	payload, err := json.Marshal(session)
+	if err != nil {
+		http.Error(w, "Error: Failed to create session", http.StatusInternalServerError)
+		log.Error("failed to marshal session", "error", err)
+		return
+	}
+	if err := a.sessions.Set(context.Background(), sessionKeyPrefix+token, payload, a.sessionTTL).Err(); err != nil {
+		http.Error(w, "Error: Failed to create session", http.StatusInternalServerError)
+		log.Error("failed to store session", "error", err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(signinResponse{Token: token})
+}
+
+// generateToken returns a random, hex-encoded session token
+func generateToken() (string, error) {
+	buf := make([]byte, 32)
+	if _, err := rand.Read(buf); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(buf), nil
+}
+
+// errInvalidSession is returned by VerifyToken when the token is missing,
+// expired or does not match a known session
+var errInvalidSession = errors.New("invalid or expired session")
+
+// VerifyToken loads and decodes the session for a token, shared by the HTTP
+// middleware and the gRPC auth interceptor.
+func (a *AuthService) VerifyToken(ctx context.Context, token string) (*Session, error) {
+	if token == "" {
+		return nil, errInvalidSession
+	}
+
+	payload, err := a.sessions.Get(ctx, sessionKeyPrefix+token).Bytes()
+	if err != nil {
+		if err == redis.Nil {
+			return nil, errInvalidSession
+		}
+		return nil, fmt.Errorf("failed to load session: %w", err)
+	}
+
+	var session Session
+	if err := json.Unmarshal(payload, &session); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal session: %w", err)
+	}
+	return &session, nil
+}
+
+// Middleware extracts the "Authorization: Bearer <token>" header, loads the
+// matching session from Redis and injects the authenticated user into the
+// request context. Requests without a valid session are rejected with 401,
+// except for the signup/signin routes themselves.
+func (a *AuthService) Middleware(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/auth/signup" || r.URL.Path == "/auth/signin" {
+			next.ServeHTTP(w, r)
+			return
+		}
+
+		token := strings.TrimPrefix(r.Header.Get("Authorization"), "Bearer ")
+		session, err := a.VerifyToken(r.Context(), token)
+		if err != nil {
+			if errors.Is(err, errInvalidSession) {
+				http.Error(w, "Error: Missing, invalid or expired authorization token", http.StatusUnauthorized)
+				return
+			}
+			http.Error(w, "Error: Failed to verify session", http.StatusInternalServerError)
+			requestLogger(r.Context()).Error("failed to verify session", "error", err)
+			return
+		}
+
+		ctx := context.WithValue(r.Context(), authUserContextKey, session)
+		next.ServeHTTP(w, r.WithContext(ctx))
+	})
+}
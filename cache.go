@@ -1,83 +1,205 @@
 package main
 
 import (
+	"bytes"
 	"context"
+	"encoding/gob"
 	"encoding/json"
-	"fmt"
+	"errors"
+	"log/slog"
+	"math/rand"
+	"time"
 
 	"github.com/redis/go-redis/v9"
 )
 
+// ErrUserNotFound is returned by UserCacher.Get when the key holds a negative
+// cache entry, i.e. a prior lookup already established the user doesn't exist.
+var ErrUserNotFound = errors.New("user not found")
+
+// notFoundSentinel is the value stored for a negative cache entry
+const notFoundSentinel = "__notfound__"
+
 // UserCacher defines the interface for caching user data
 type UserCacher interface {
-	Get(key string) (*User, error)
-	Set(key string, user *User) error
+	// Get returns the cached user, or nil if there is no cache entry. It
+	// returns ErrUserNotFound if the key holds a negative cache entry.
+	Get(key string) (*PublicUser, error)
+	Set(key string, user *PublicUser) error
+	SetWithTTL(key string, user *PublicUser, ttl time.Duration) error
+	// SetNotFound records that the user for key doesn't exist, so repeated
+	// misses short-circuit without touching the database until it expires.
+	SetNotFound(key string) error
 	Del(key string) error
 }
 
+// Codec marshals and unmarshals a PublicUser for storage in the cache
+type Codec interface {
+	Marshal(user *PublicUser) ([]byte, error)
+	Unmarshal(data []byte, user *PublicUser) error
+}
+
+// JSONCodec encodes users as JSON
+type JSONCodec struct{}
+
+// Marshal encodes a user as JSON
+func (JSONCodec) Marshal(user *PublicUser) ([]byte, error) {
+	return json.Marshal(user)
+}
+
+// Unmarshal decodes a JSON-encoded user
+func (JSONCodec) Unmarshal(data []byte, user *PublicUser) error {
+	return json.Unmarshal(data, user)
+}
+
+// GobCodec encodes users using encoding/gob
+type GobCodec struct{}
+
+// Marshal encodes a user using gob
+func (GobCodec) Marshal(user *PublicUser) ([]byte, error) {
+	var buf bytes.Buffer
+	if err := gob.NewEncoder(&buf).Encode(user); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+// Unmarshal decodes a gob-encoded user
+func (GobCodec) Unmarshal(data []byte, user *PublicUser) error {
+	return gob.NewDecoder(bytes.NewReader(data)).Decode(user)
+}
+
+// CacheOptions configures a RedisUserCacher
+type CacheOptions struct {
+	// DefaultTTL is used by Set when no explicit TTL is given. Zero means no expiration.
+	DefaultTTL time.Duration
+	// KeyPrefix is prepended to every key, letting multiple apps share one Redis.
+	KeyPrefix string
+	// Codec controls how users are serialized before being stored in Redis.
+	Codec Codec
+	// TTLJitter is the maximum fraction (0-1) of randomness added to DefaultTTL,
+	// spreading out expirations so they don't all evict at once.
+	TTLJitter float64
+	// NegativeTTL controls how long a SetNotFound entry is kept before a
+	// lookup for that key is allowed to hit the database again.
+	NegativeTTL time.Duration
+}
+
+// DefaultCacheOptions returns the options used before CacheOptions existed:
+// JSON encoding, no key prefix, no expiration, and a 30s negative cache TTL.
+func DefaultCacheOptions() CacheOptions {
+	return CacheOptions{
+		Codec:       JSONCodec{},
+		NegativeTTL: 30 * time.Second,
+	}
+}
+
 // RedisUserCacher implements UserCacher interface for Redis cache
 type RedisUserCacher struct {
 	client *redis.Client
+	opts   CacheOptions
+}
+
+// NewRedisUserCacher creates a new RedisUserCacher for caching user data.
+// A zero-value Codec in opts defaults to JSONCodec.
+func NewRedisUserCacher(cache *redis.Client, opts CacheOptions) UserCacher {
+	if opts.Codec == nil {
+		opts.Codec = JSONCodec{}
+	}
+	return &RedisUserCacher{client: cache, opts: opts}
 }
 
-// NewRedisUserCacher creates a new Redis client for caching user data
-func NewRedisUserCacher(cache *redis.Client) UserCacher {
-	return &RedisUserCacher{client: cache}
+// key applies the configured key prefix
+func (r *RedisUserCacher) key(key string) string {
+	return r.opts.KeyPrefix + key
 }
 
 // Del deletes a user from the Redis cache by key
 func (r *RedisUserCacher) Del(key string) error {
-	result, err := r.client.Del(context.Background(), key).Result()
+	result, err := r.client.Del(context.Background(), r.key(key)).Result()
 	if err != nil {
-		fmt.Println("Error deleting user from cache:", err)
+		slog.Error("failed to delete user from cache", "key", key, "error", err)
 		return err
 	}
 	if result == 0 {
-		fmt.Printf("Cache MISS: User %s not found in Redis\n", key)
+		slog.Info("cache miss on delete", "key", key)
 		return nil // No error for cache miss, just log it
 	}
 	// Successfully deleted from cache
-	fmt.Println("User deleted from cache:", key)
+	slog.Info("user deleted from cache", "key", key)
 	return nil
 }
 
 // Get retrieves a user from the Redis cache by key
-func (r *RedisUserCacher) Get(key string) (*User, error) {
-	val, err := r.client.Get(context.Background(), key).Result()
+func (r *RedisUserCacher) Get(key string) (*PublicUser, error) {
+	val, err := r.client.Get(context.Background(), r.key(key)).Bytes()
 	if err != nil {
 		// Check if it's a cache miss (key not found)
 		if err == redis.Nil {
-			fmt.Printf("Cache MISS: User %s not found in Redis, checking database\n", key)
+			slog.Info("cache miss, checking database", "key", key)
 			return nil, nil // Return nil user and nil error for cache miss
 		}
 		// This is an actual Redis error (connection issues, etc.)
-		fmt.Println("Error retrieving user from cache:", err)
+		slog.Error("failed to retrieve user from cache", "key", key, "error", err)
 		return nil, err
 	}
 
-	// Key exists in cache, unmarshal the data
-	var user User
-	if err := json.Unmarshal([]byte(val), &user); err != nil {
-		fmt.Println("Error unmarshalling user data:", err)
+	if string(val) == notFoundSentinel {
+		slog.Info("negative cache hit", "key", key)
+		return nil, ErrUserNotFound
+	}
+
+	// Key exists in cache, decode the data
+	var user PublicUser
+	if err := r.opts.Codec.Unmarshal(val, &user); err != nil {
+		slog.Error("failed to unmarshal cached user data", "key", key, "error", err)
 		return nil, err
 	}
-	fmt.Printf("Cache HIT: Retrieved user %s from Redis\n", key)
+	slog.Info("cache hit", "key", key)
 	return &user, nil
 }
 
-// Set stores a user in the Redis cache
-// It marshals the user data to JSON format before storing
-func (r *RedisUserCacher) Set(key string, user *User) error {
-	userData, err := json.Marshal(user)
+// SetNotFound records a negative cache entry for key, expiring after NegativeTTL
+// (default 30s). A zero NegativeTTL disables expiration, which is almost
+// certainly not what's wanted, so DefaultCacheOptions always sets one.
+func (r *RedisUserCacher) SetNotFound(key string) error {
+	err := r.client.Set(context.Background(), r.key(key), notFoundSentinel, r.opts.NegativeTTL).Err()
+	if err != nil {
+		slog.Error("failed to store negative cache entry", "key", key, "error", err)
+		return err
+	}
+	slog.Info("negative cache entry stored", "key", key, "ttl", r.opts.NegativeTTL)
+	return nil
+}
+
+// Set stores a user in the Redis cache using the configured default TTL (jittered, if set)
+func (r *RedisUserCacher) Set(key string, user *PublicUser) error {
+	return r.SetWithTTL(key, user, r.jitteredTTL())
+}
+
+// SetWithTTL stores a user in the Redis cache with an explicit expiration.
+// A ttl of 0 means no expiration.
+func (r *RedisUserCacher) SetWithTTL(key string, user *PublicUser, ttl time.Duration) error {
+	userData, err := r.opts.Codec.Marshal(user)
 	if err != nil {
-		fmt.Println("Error marshalling user data:", err)
+		slog.Error("failed to marshal user data", "key", key, "error", err)
 		return err
 	}
-	err = r.client.Set(context.Background(), key, userData, 0).Err()
+	err = r.client.Set(context.Background(), r.key(key), userData, ttl).Err()
 	if err != nil {
-		fmt.Println("Error updating user in cache:", err)
+		slog.Error("failed to store user in cache", "key", key, "error", err)
 		return err
 	}
-	fmt.Println("User stored in cache:", *user)
+	slog.Info("user stored in cache", "key", key, "ttl", ttl)
 	return nil
 }
+
+// jitteredTTL adds up to TTLJitter fraction of randomness to DefaultTTL so that
+// entries written around the same time don't all expire at once.
+func (r *RedisUserCacher) jitteredTTL() time.Duration {
+	if r.opts.DefaultTTL <= 0 || r.opts.TTLJitter <= 0 {
+		return r.opts.DefaultTTL
+	}
+	jitter := time.Duration(rand.Float64() * r.opts.TTLJitter * float64(r.opts.DefaultTTL))
+	return r.opts.DefaultTTL + jitter
+}
@@ -0,0 +1,49 @@
+// Command client is a minimal gRPC client for UserService, useful for
+// manually exercising the gRPC transport during development.
+package main
+
+import (
+	"context"
+	"flag"
+	"log"
+	"time"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/metadata"
+
+	userv1 "github.com/andreistefanciprian/go-rest-cache-pubsub/proto/user/v1"
+)
+
+// withAuthorization attaches a "Bearer <token>" authorization header to the
+// outgoing gRPC request, matching what the server's auth interceptor expects
+func withAuthorization(ctx context.Context, token string) context.Context {
+	return metadata.AppendToOutgoingContext(ctx, "authorization", "Bearer "+token)
+}
+
+func main() {
+	addr := flag.String("addr", "localhost:9090", "gRPC server address")
+	id := flag.Uint("id", 1, "id of the user to fetch")
+	token := flag.String("token", "", "session token to authenticate with")
+	flag.Parse()
+
+	conn, err := grpc.NewClient(*addr, grpc.WithTransportCredentials(insecure.NewCredentials()))
+	if err != nil {
+		log.Fatalf("failed to connect to %s: %v", *addr, err)
+	}
+	defer conn.Close()
+
+	client := userv1.NewUserServiceClient(conn)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	if *token != "" {
+		ctx = withAuthorization(ctx, *token)
+	}
+
+	resp, err := client.GetUser(ctx, &userv1.GetUserRequest{Id: uint32(*id)})
+	if err != nil {
+		log.Fatalf("GetUser failed: %v", err)
+	}
+	log.Printf("got user: %+v", resp.User)
+}
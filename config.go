@@ -0,0 +1,67 @@
+package main
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/caarlos0/env/v8"
+)
+
+// Config holds all runtime configuration for the service, parsed from
+// environment variables via github.com/caarlos0/env.
+type Config struct {
+	DBHost     string `env:"DB_HOST" envDefault:"localhost"`
+	DBPort     string `env:"DB_PORT" envDefault:"5432"`
+	DBUser     string `env:"DB_USER" envDefault:"postgres"`
+	DBPassword string `env:"DB_PASSWORD" envDefault:"password"`
+	DBName     string `env:"DB_NAME" envDefault:"users"`
+
+	RedisHost     string `env:"REDIS_HOST" envDefault:"localhost"`
+	RedisPort     string `env:"REDIS_PORT" envDefault:"6379"`
+	RedisPassword string `env:"REDIS_PASSWORD" envDefault:"redispassword"`
+
+	RedisTTL         time.Duration `env:"REDIS_TTL" envDefault:"0s"`
+	RedisKeyPrefix   string        `env:"REDIS_KEY_PREFIX" envDefault:""`
+	CacheCodec       string        `env:"CACHE_CODEC" envDefault:"json"`
+	CacheNegativeTTL time.Duration `env:"CACHE_NEGATIVE_TTL" envDefault:"30s"`
+
+	InstanceID string        `env:"INSTANCE_ID"`
+	SessionTTL time.Duration `env:"SESSION_TTL" envDefault:"24h"`
+
+	HTTPAddr        string        `env:"HTTP_ADDR" envDefault:":8080"`
+	GRPCAddr        string        `env:"GRPC_ADDR" envDefault:":9090"`
+	ReadTimeout     time.Duration `env:"READ_TIMEOUT" envDefault:"5s"`
+	WriteTimeout    time.Duration `env:"WRITE_TIMEOUT" envDefault:"10s"`
+	ShutdownTimeout time.Duration `env:"SHUTDOWN_TIMEOUT" envDefault:"15s"`
+
+	LogLevel string `env:"LOG_LEVEL" envDefault:"info"`
+}
+
+// LoadConfig parses Config from the environment
+func LoadConfig() (*Config, error) {
+	cfg := &Config{}
+	if err := env.Parse(cfg); err != nil {
+		return nil, fmt.Errorf("failed to parse config: %w", err)
+	}
+	return cfg, nil
+}
+
+// cacheOptions builds the CacheOptions RedisUserCacher is configured with
+func (c *Config) cacheOptions() CacheOptions {
+	opts := DefaultCacheOptions()
+	opts.KeyPrefix = c.RedisKeyPrefix
+	opts.DefaultTTL = c.RedisTTL
+	opts.NegativeTTL = c.CacheNegativeTTL
+	if c.RedisTTL > 0 {
+		opts.TTLJitter = 0.1
+	}
+
+	switch c.CacheCodec {
+	case "gob":
+		opts.Codec = GobCodec{}
+	default:
+		opts.Codec = JSONCodec{}
+	}
+
+	return opts
+}
@@ -1,7 +1,7 @@
 package main
 
 import (
-	"fmt"
+	"log/slog"
 	"time"
 
 	"gorm.io/gorm"
@@ -12,15 +12,44 @@ type UserStorer interface {
 	CreateUser(user *User) error
 	GetUsers() ([]User, error)
 	GetUser(id int) (*User, error)
+	GetUserByEmail(email string) (*User, error)
 	UpdateUser(user *User, updates User) error
 	DeleteUser(id int) error
 }
 
+// User roles recognized by the auth subsystem
+const (
+	RoleHost   = "host"
+	RoleMember = "member"
+)
+
 // User represents a user in the system
 // It includes gorm.Model which provides ID, CreatedAt, UpdatedAt, DeletedAt fields
 type User struct {
 	gorm.Model
-	Name string `json:"name"`
+	Name         string `json:"name"`
+	Email        string `json:"email" gorm:"uniqueIndex"`
+	PasswordHash string `json:"-"`
+	Role         string `json:"role"`
+}
+
+// PublicUser is the User DTO returned to clients and stored in the cache.
+// It omits PasswordHash so a cache entry can never leak credentials.
+type PublicUser struct {
+	ID    uint   `json:"id"`
+	Name  string `json:"name"`
+	Email string `json:"email"`
+	Role  string `json:"role"`
+}
+
+// Public returns the PublicUser view of a User
+func (u *User) Public() *PublicUser {
+	return &PublicUser{
+		ID:    u.ID,
+		Name:  u.Name,
+		Email: u.Email,
+		Role:  u.Role,
+	}
 }
 
 // PostgreSQLUserStorer implements UserStorer interface for PostgreSQL database
@@ -39,26 +68,30 @@ func NewPostgreSQLUserStorer(db *gorm.DB) UserStorer {
 func (r *PostgreSQLUserStorer) UpdateUser(user *User, updates User) error {
 	// Simulate database delay
 	time.Sleep(500 * time.Millisecond)
-	fmt.Printf("Updating user %d in database\n", user.ID)
+	slog.Info("updating user in database", "id", user.ID)
 	// First check if user exists
 	var existingUser User
 	result := r.db.First(&existingUser, user.ID)
 	if result.Error != nil {
 		if result.Error == gorm.ErrRecordNotFound {
-			fmt.Printf("User %d not found in database\n", user.ID)
+			slog.Info("user not found in database", "id", user.ID)
 			return gorm.ErrRecordNotFound
 		}
-		fmt.Println("Error checking user existence:", result.Error)
+		slog.Error("failed to check user existence", "id", user.ID, "error", result.Error)
 		return result.Error
 	}
 	// User exists, proceed with update
 	existingUser.Name = updates.Name
 	result = r.db.Save(&existingUser)
 	if result.Error != nil {
-		fmt.Println("Error updating user:", result.Error)
+		slog.Error("failed to update user", "id", user.ID, "error", result.Error)
 		return result.Error
 	}
-	fmt.Printf("User %d updated in database: %+v\n", user.ID, existingUser)
+	slog.Info("user updated in database", "id", user.ID)
+	// Populate the caller's pointer with the full, saved row so callers that
+	// build it from just an ID and a name (e.g. the HTTP/gRPC handlers) don't
+	// blank out Email/Role when caching or returning it.
+	*user = existingUser
 	return nil
 }
 
@@ -66,27 +99,27 @@ func (r *PostgreSQLUserStorer) UpdateUser(user *User, updates User) error {
 func (r *PostgreSQLUserStorer) DeleteUser(id int) error {
 	// Simulate database delay
 	time.Sleep(500 * time.Millisecond)
-	fmt.Printf("Deleting user %d from database\n", id)
+	slog.Info("deleting user from database", "id", id)
 
 	// First check if user exists
 	var user User
 	result := r.db.First(&user, id)
 	if result.Error != nil {
 		if result.Error == gorm.ErrRecordNotFound {
-			fmt.Printf("User %d not found in database\n", id)
+			slog.Info("user not found in database", "id", id)
 			return gorm.ErrRecordNotFound
 		}
-		fmt.Println("Error checking user existence:", result.Error)
+		slog.Error("failed to check user existence", "id", id, "error", result.Error)
 		return result.Error
 	}
 
 	// User exists, proceed with deletion
 	result = r.db.Delete(&user, id)
 	if result.Error != nil {
-		fmt.Println("Error deleting user:", result.Error)
+		slog.Error("failed to delete user", "id", id, "error", result.Error)
 		return result.Error
 	}
-	fmt.Printf("User %d deleted from database\n", id)
+	slog.Info("user deleted from database", "id", id)
 	return nil
 }
 
@@ -94,15 +127,31 @@ func (r *PostgreSQLUserStorer) DeleteUser(id int) error {
 func (r *PostgreSQLUserStorer) GetUser(id int) (*User, error) {
 	// Simulate database delay
 	time.Sleep(500 * time.Millisecond)
-	fmt.Printf("Getting user %d from database\n", id)
+	slog.Info("getting user from database", "id", id)
 
 	var user User
 	result := r.db.First(&user, id)
 	if result.Error != nil {
-		fmt.Println("Error retrieving user:", result.Error)
+		slog.Error("failed to retrieve user", "id", id, "error", result.Error)
+		return nil, result.Error
+	}
+	slog.Info("user retrieved from database", "id", id)
+	return &user, nil
+}
+
+// GetUserByEmail retrieves a user by email address from the database
+func (r *PostgreSQLUserStorer) GetUserByEmail(email string) (*User, error) {
+	// Simulate database delay
+	time.Sleep(500 * time.Millisecond)
+	slog.Info("getting user from database by email", "email", email)
+
+	var user User
+	result := r.db.Where("email = ?", email).First(&user)
+	if result.Error != nil {
+		slog.Error("failed to retrieve user by email", "email", email, "error", result.Error)
 		return nil, result.Error
 	}
-	fmt.Println("User retrieved from database:", user)
+	slog.Info("user retrieved from database", "id", user.ID)
 	return &user, nil
 }
 
@@ -110,10 +159,10 @@ func (r *PostgreSQLUserStorer) GetUser(id int) (*User, error) {
 func (r *PostgreSQLUserStorer) CreateUser(user *User) error {
 	result := r.db.Create(user)
 	if result.Error != nil {
-		fmt.Println("Error creating user:", result.Error)
+		slog.Error("failed to create user", "error", result.Error)
 		return result.Error
 	}
-	fmt.Println("User created:", user)
+	slog.Info("user created", "id", user.ID)
 	return nil
 }
 
@@ -122,9 +171,9 @@ func (r *PostgreSQLUserStorer) GetUsers() ([]User, error) {
 	var users []User
 	result := r.db.Find(&users)
 	if result.Error != nil {
-		fmt.Println("Error retrieving users:", result.Error)
+		slog.Error("failed to retrieve users", "error", result.Error)
 		return nil, result.Error
 	}
-	fmt.Println("Users retrieved:", users)
+	slog.Info("users retrieved", "count", len(users))
 	return users, nil
 }
@@ -0,0 +1,119 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"log/slog"
+	"strconv"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+)
+
+// userEventsChannel is the Redis Pub/Sub channel user CRUD events are published on
+const userEventsChannel = "user.events"
+
+// User CRUD event operations carried in UserEvent.Op
+const (
+	UserCreatedOp = "user.created"
+	UserUpdatedOp = "user.updated"
+	UserDeletedOp = "user.deleted"
+)
+
+// UserEvent represents a user CRUD event published on the user events channel
+type UserEvent struct {
+	ID         uint   `json:"id"`
+	Name       string `json:"name"`
+	Op         string `json:"op"`
+	Ts         int64  `json:"ts"`
+	InstanceID string `json:"instance_id"`
+}
+
+// UserEventBus publishes and subscribes to user CRUD events over Redis Pub/Sub.
+// It lets multiple service instances sharing one Redis and Postgres keep their
+// local caches coherent whenever any instance writes a user.
+type UserEventBus struct {
+	client     *redis.Client
+	instanceID string
+}
+
+// NewUserEventBus creates a new UserEventBus bound to the given Redis client.
+// instanceID identifies this process among others sharing the same channel, so
+// Subscribe can skip events this instance published itself.
+func NewUserEventBus(client *redis.Client, instanceID string) *UserEventBus {
+	return &UserEventBus{client: client, instanceID: instanceID}
+}
+
+// Publish sends a user CRUD event for the given user on the shared Redis channel
+func (b *UserEventBus) Publish(op string, id uint, name string) error {
+	event := UserEvent{
+		ID:         id,
+		Name:       name,
+		Op:         op,
+		Ts:         time.Now().Unix(),
+		InstanceID: b.instanceID,
+	}
+	payload, err := json.Marshal(event)
+	if err != nil {
+		slog.Error("failed to marshal user event", "op", op, "id", id, "error", err)
+		return err
+	}
+	if err := b.client.Publish(context.Background(), userEventsChannel, payload).Err(); err != nil {
+		slog.Error("failed to publish user event", "op", op, "id", id, "error", err)
+		return err
+	}
+	slog.Info("published user event", "op", op, "id", id)
+	return nil
+}
+
+// Subscribe listens for user CRUD events on the shared Redis channel until ctx
+// is cancelled. For every event published by another instance it invalidates,
+// and for creates/updates refreshes, the local cache entry for the affected user.
+func (b *UserEventBus) Subscribe(ctx context.Context, cacher UserCacher, storer UserStorer) {
+	sub := b.client.Subscribe(ctx, userEventsChannel)
+	defer sub.Close()
+
+	slog.Info("subscribed to user events", "channel", userEventsChannel)
+	ch := sub.Channel()
+	for {
+		select {
+		case <-ctx.Done():
+			slog.Info("stopping user event subscriber")
+			return
+		case msg, ok := <-ch:
+			if !ok {
+				return
+			}
+			b.handleMessage(msg.Payload, cacher, storer)
+		}
+	}
+}
+
+// handleMessage decodes a single pub/sub message and applies it to the local cache
+func (b *UserEventBus) handleMessage(payload string, cacher UserCacher, storer UserStorer) {
+	var event UserEvent
+	if err := json.Unmarshal([]byte(payload), &event); err != nil {
+		slog.Error("failed to unmarshal user event", "error", err)
+		return
+	}
+	if event.InstanceID == b.instanceID {
+		return // skip events this instance published itself
+	}
+
+	key := strconv.Itoa(int(event.ID))
+	switch event.Op {
+	case UserDeletedOp:
+		if err := cacher.Del(key); err != nil {
+			slog.Error("failed to invalidate cache for user", "id", event.ID, "error", err)
+		}
+	case UserCreatedOp, UserUpdatedOp:
+		user, err := storer.GetUser(int(event.ID))
+		if err != nil {
+			slog.Error("failed to refresh cache for user", "id", event.ID, "error", err)
+			return
+		}
+		if err := cacher.Set(key, user.Public()); err != nil {
+			slog.Error("failed to refresh cache for user", "id", event.ID, "error", err)
+		}
+	}
+}
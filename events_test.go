@@ -0,0 +1,196 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+	"gorm.io/gorm"
+)
+
+// fakeStorer is a minimal UserStorer backed by an in-memory map, used so the
+// pub/sub test below only needs a real Redis, not a real Postgres.
+type fakeStorer struct {
+	mu    sync.Mutex
+	users map[int]*User
+}
+
+func newFakeStorer(users ...*User) *fakeStorer {
+	s := &fakeStorer{users: make(map[int]*User)}
+	for _, u := range users {
+		s.users[int(u.ID)] = u
+	}
+	return s
+}
+
+func (s *fakeStorer) CreateUser(user *User) error { return fmt.Errorf("not implemented") }
+func (s *fakeStorer) GetUsers() ([]User, error)   { return nil, fmt.Errorf("not implemented") }
+
+func (s *fakeStorer) GetUser(id int) (*User, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	user, ok := s.users[id]
+	if !ok {
+		return nil, fmt.Errorf("user %d not found", id)
+	}
+	return user, nil
+}
+
+func (s *fakeStorer) GetUserByEmail(email string) (*User, error) {
+	return nil, fmt.Errorf("not implemented")
+}
+func (s *fakeStorer) UpdateUser(user *User, updates User) error { return fmt.Errorf("not implemented") }
+func (s *fakeStorer) DeleteUser(id int) error                   { return fmt.Errorf("not implemented") }
+
+// fakeCacher is a minimal, in-memory UserCacher standing in for each
+// instance's own cache, so tests can assert what happened without needing a
+// second Redis key space. It mirrors RedisUserCacher's negative-cache
+// semantics: Get returns ErrUserNotFound for a key set via SetNotFound.
+type fakeCacher struct {
+	mu        sync.Mutex
+	entries   map[string]*PublicUser
+	notFound  map[string]bool
+	deleted   map[string]bool
+}
+
+func newFakeCacher() *fakeCacher {
+	return &fakeCacher{
+		entries:  make(map[string]*PublicUser),
+		notFound: make(map[string]bool),
+		deleted:  make(map[string]bool),
+	}
+}
+
+func (c *fakeCacher) Get(key string) (*PublicUser, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.notFound[key] {
+		return nil, ErrUserNotFound
+	}
+	return c.entries[key], nil
+}
+
+func (c *fakeCacher) Set(key string, user *PublicUser) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.entries[key] = user
+	delete(c.notFound, key)
+	delete(c.deleted, key)
+	return nil
+}
+
+func (c *fakeCacher) SetWithTTL(key string, user *PublicUser, ttl time.Duration) error {
+	return c.Set(key, user)
+}
+
+func (c *fakeCacher) SetNotFound(key string) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.notFound[key] = true
+	return nil
+}
+
+func (c *fakeCacher) Del(key string) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	delete(c.entries, key)
+	delete(c.notFound, key)
+	c.deleted[key] = true
+	return nil
+}
+
+func (c *fakeCacher) snapshot(key string) (user *PublicUser, wasDeleted bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.entries[key], c.deleted[key]
+}
+
+// testRedisAddr returns the Redis address used for integration tests,
+// overridable via REDIS_TEST_ADDR for environments where it's not localhost.
+func testRedisAddr() string {
+	if addr := os.Getenv("REDIS_TEST_ADDR"); addr != "" {
+		return addr
+	}
+	return "localhost:6379"
+}
+
+// requireRedis connects to testRedisAddr and skips the test if Redis isn't
+// reachable, since this test exercises real Redis Pub/Sub across two
+// simulated service instances rather than mocking it out.
+func requireRedis(t *testing.T) *redis.Client {
+	t.Helper()
+	client := redis.NewClient(&redis.Options{Addr: testRedisAddr()})
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+	if err := client.Ping(ctx).Err(); err != nil {
+		t.Skipf("skipping integration test: redis not reachable at %s: %v", testRedisAddr(), err)
+	}
+	t.Cleanup(func() { client.Close() })
+	return client
+}
+
+// waitFor polls cond until it returns true or timeout elapses, failing the test otherwise.
+func waitFor(t *testing.T, timeout time.Duration, cond func() bool) {
+	t.Helper()
+	deadline := time.Now().Add(timeout)
+	for time.Now().Before(deadline) {
+		if cond() {
+			return
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+	t.Fatal("condition not met before timeout")
+}
+
+// TestUserEventBus_CrossInstanceInvalidation runs two UserEventBus instances
+// sharing one Redis, mirroring two service replicas: publishing an event on
+// one must invalidate/refresh the other's cache, and an instance must ignore
+// its own published events.
+func TestUserEventBus_CrossInstanceInvalidation(t *testing.T) {
+	client := requireRedis(t)
+
+	storer := newFakeStorer(&User{Model: gorm.Model{ID: 1}, Name: "Ada Lovelace", Role: RoleMember})
+
+	instanceA := NewUserEventBus(client, "instance-a")
+	instanceB := NewUserEventBus(client, "instance-b")
+
+	cacheA := newFakeCacher()
+	cacheB := newFakeCacher()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	go instanceA.Subscribe(ctx, cacheA, storer)
+	go instanceB.Subscribe(ctx, cacheB, storer)
+
+	// Give both subscribers time to establish their Redis Pub/Sub subscription.
+	time.Sleep(200 * time.Millisecond)
+
+	cacheB.Set("1", &PublicUser{ID: 1, Name: "stale name"})
+
+	if err := instanceA.Publish(UserUpdatedOp, 1, "Ada Lovelace"); err != nil {
+		t.Fatalf("Publish failed: %v", err)
+	}
+
+	waitFor(t, 2*time.Second, func() bool {
+		user, _ := cacheB.snapshot("1")
+		return user != nil && user.Name == "Ada Lovelace"
+	})
+
+	// instance A published the event itself, so its own cache must be untouched.
+	if user, _ := cacheA.snapshot("1"); user != nil {
+		t.Fatalf("instance A applied its own published event to its own cache: %+v", user)
+	}
+
+	if err := instanceA.Publish(UserDeletedOp, 1, ""); err != nil {
+		t.Fatalf("Publish failed: %v", err)
+	}
+
+	waitFor(t, 2*time.Second, func() bool {
+		_, wasDeleted := cacheB.snapshot("1")
+		return wasDeleted
+	})
+}
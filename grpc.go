@@ -0,0 +1,188 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"strconv"
+	"strings"
+	"time"
+
+	"golang.org/x/sync/singleflight"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/health/grpc_health_v1"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+	"gorm.io/gorm"
+
+	userv1 "github.com/andreistefanciprian/go-rest-cache-pubsub/proto/user/v1"
+)
+
+// GRPCUserServer implements userv1.UserServiceServer. It reuses the same
+// UserStorer, UserCacher and UserEventBus as the HTTP UserService, so the
+// cache-aside reads and pub/sub cache invalidation are shared between the
+// two transports.
+type GRPCUserServer struct {
+	userv1.UnimplementedUserServiceServer
+	storer   UserStorer
+	cacher   UserCacher
+	events   *UserEventBus
+	getGroup singleflight.Group
+}
+
+// NewGRPCUserServer creates a new GRPCUserServer
+func NewGRPCUserServer(storer UserStorer, cacher UserCacher, events *UserEventBus) *GRPCUserServer {
+	return &GRPCUserServer{storer: storer, cacher: cacher, events: events}
+}
+
+// toProtoUser converts the internal PublicUser DTO to its proto representation
+func toProtoUser(u *PublicUser) *userv1.User {
+	return &userv1.User{Id: uint32(u.ID), Name: u.Name, Email: u.Email, Role: u.Role}
+}
+
+// GetUser retrieves a user, checking the cache (including the negative cache
+// for known-missing IDs) first and collapsing concurrent cold misses for the
+// same ID into a single DB read.
+func (s *GRPCUserServer) GetUser(ctx context.Context, req *userv1.GetUserRequest) (*userv1.UserResponse, error) {
+	key := strconv.Itoa(int(req.Id))
+	cached, err := s.cacher.Get(key)
+	if err == ErrUserNotFound {
+		return nil, status.Error(codes.NotFound, "user not found")
+	}
+	if err == nil && cached != nil {
+		return &userv1.UserResponse{User: toProtoUser(cached)}, nil
+	}
+
+	v, err, _ := s.getGroup.Do(key, func() (interface{}, error) {
+		return s.storer.GetUser(int(req.Id))
+	})
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			if err := s.cacher.SetNotFound(key); err != nil {
+				slog.Error("failed to store negative cache entry", "id", req.Id, "error", err)
+			}
+			return nil, status.Error(codes.NotFound, "user not found")
+		}
+		return nil, status.Error(codes.Internal, "failed to retrieve user")
+	}
+
+	user := v.(*User)
+	if err := s.cacher.Set(key, user.Public()); err != nil {
+		slog.Error("failed to store user in cache", "id", user.ID, "error", err)
+	}
+	return &userv1.UserResponse{User: toProtoUser(user.Public())}, nil
+}
+
+// ListUsers retrieves all users. Only a host may list every user.
+func (s *GRPCUserServer) ListUsers(ctx context.Context, req *userv1.ListUsersRequest) (*userv1.ListUsersResponse, error) {
+	authedUser, ok := UserFromContext(ctx)
+	if !ok || authedUser.Role != RoleHost {
+		return nil, status.Error(codes.PermissionDenied, "forbidden")
+	}
+
+	users, err := s.storer.GetUsers()
+	if err != nil {
+		return nil, status.Error(codes.Internal, "failed to retrieve users")
+	}
+	resp := &userv1.ListUsersResponse{Users: make([]*userv1.User, len(users))}
+	for i := range users {
+		resp.Users[i] = toProtoUser(users[i].Public())
+	}
+	return resp, nil
+}
+
+// UpdateUser updates a user, refreshes the cache and publishes a user.updated event.
+// Only the owning user or a host may update a given ID.
+func (s *GRPCUserServer) UpdateUser(ctx context.Context, req *userv1.UpdateUserRequest) (*userv1.UserResponse, error) {
+	if req.Name == "" {
+		return nil, status.Error(codes.InvalidArgument, "name is required")
+	}
+	if !canMutateUser(ctx, int(req.Id)) {
+		return nil, status.Error(codes.PermissionDenied, "forbidden")
+	}
+
+	user := &User{Model: gorm.Model{ID: uint(req.Id)}, Name: req.Name}
+	if err := s.storer.UpdateUser(user, User{Name: req.Name}); err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, status.Error(codes.NotFound, "user not found")
+		}
+		return nil, status.Error(codes.Internal, "failed to update user")
+	}
+
+	if err := s.cacher.Set(strconv.Itoa(int(req.Id)), user.Public()); err != nil {
+		slog.Error("failed to update user in cache", "id", user.ID, "error", err)
+	}
+	if err := s.events.Publish(UserUpdatedOp, user.ID, user.Name); err != nil {
+		slog.Error("failed to publish user.updated event", "id", user.ID, "error", err)
+	}
+
+	return &userv1.UserResponse{User: toProtoUser(user.Public())}, nil
+}
+
+// DeleteUser deletes a user, invalidates the cache and publishes a user.deleted event.
+// Only the owning user or a host may delete a given ID.
+func (s *GRPCUserServer) DeleteUser(ctx context.Context, req *userv1.DeleteUserRequest) (*userv1.DeleteUserResponse, error) {
+	if !canMutateUser(ctx, int(req.Id)) {
+		return nil, status.Error(codes.PermissionDenied, "forbidden")
+	}
+
+	if err := s.storer.DeleteUser(int(req.Id)); err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, status.Error(codes.NotFound, "user not found")
+		}
+		return nil, status.Error(codes.Internal, "failed to delete user")
+	}
+
+	if err := s.cacher.Del(strconv.Itoa(int(req.Id))); err != nil {
+		slog.Error("failed to delete user from cache", "id", req.Id, "error", err)
+	}
+	if err := s.events.Publish(UserDeletedOp, uint(req.Id), ""); err != nil {
+		slog.Error("failed to publish user.deleted event", "id", req.Id, "error", err)
+	}
+
+	return &userv1.DeleteUserResponse{}, nil
+}
+
+// healthServiceMethodPrefix is the method prefix for grpc.health.v1.Health,
+// exempted from auth the same way HTTP exempts /auth/signup and /auth/signin
+var healthServiceMethodPrefix = "/" + grpc_health_v1.Health_ServiceDesc.ServiceName + "/"
+
+// unaryLoggingAuthInterceptor, mirroring the HTTP auth middleware, requires a
+// valid session token for every method except grpc.health.v1.Health, and logs
+// each authenticated call once it completes. Health-check calls pass through
+// without authentication or logging; rejected calls are logged as auth failures.
+func unaryLoggingAuthInterceptor(auth *AuthService) grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
+		start := time.Now()
+
+		if strings.HasPrefix(info.FullMethod, healthServiceMethodPrefix) {
+			return handler(ctx, req)
+		}
+
+		token := tokenFromMetadata(ctx)
+		session, err := auth.VerifyToken(ctx, token)
+		if err != nil {
+			slog.Error("grpc auth failed", "method", info.FullMethod, "error", err)
+			return nil, status.Error(codes.Unauthenticated, "missing, invalid or expired authorization token")
+		}
+		ctx = context.WithValue(ctx, authUserContextKey, session)
+
+		resp, err := handler(ctx, req)
+		slog.Info("handled grpc request", "method", info.FullMethod, "duration", time.Since(start), "error", err)
+		return resp, err
+	}
+}
+
+// tokenFromMetadata extracts the bearer token from the "authorization" metadata key
+func tokenFromMetadata(ctx context.Context) string {
+	md, ok := metadata.FromIncomingContext(ctx)
+	if !ok {
+		return ""
+	}
+	values := md.Get("authorization")
+	if len(values) == 0 {
+		return ""
+	}
+	return strings.TrimPrefix(values[0], "Bearer ")
+}
@@ -1,17 +1,20 @@
 package main
 
 import (
+	"context"
 	"encoding/json"
 	"fmt"
 	"net/http"
 	"strconv"
 
+	"golang.org/x/sync/singleflight"
 	"gorm.io/gorm"
 )
 
-// HTTPUserHandler defines the interface for handling user-related HTTP requests
+// HTTPUserHandler defines the interface for handling user-related HTTP requests.
+// User creation happens through AuthService.SignUp, which is the only path that
+// sets Email, PasswordHash and Role consistently.
 type HTTPUserHandler interface {
-	CreateUser(w http.ResponseWriter, r *http.Request)
 	GetAllUsers(w http.ResponseWriter, r *http.Request)
 	GetUserById(w http.ResponseWriter, r *http.Request)
 	UpdateUserById(w http.ResponseWriter, r *http.Request)
@@ -21,17 +24,22 @@ type HTTPUserHandler interface {
 // UserService implements HTTPUserHandler interface
 // It provides methods to handle user-related HTTP requests
 // It uses UserStorer for database operations and UserCacher for caching
+// It publishes user CRUD events on events so other instances can invalidate their caches
+// getGroup collapses concurrent cache misses for the same ID into a single DB read
 type UserService struct {
-	storer UserStorer
-	cacher UserCacher
+	storer   UserStorer
+	cacher   UserCacher
+	events   *UserEventBus
+	getGroup singleflight.Group
 }
 
 // NewUserService creates a new UserService instance
-// It initializes the service with the provided UserStorer and UserCacher
-func NewUserService(storer UserStorer, cacher UserCacher) HTTPUserHandler {
+// It initializes the service with the provided UserStorer, UserCacher and UserEventBus
+func NewUserService(storer UserStorer, cacher UserCacher, events *UserEventBus) HTTPUserHandler {
 	return &UserService{
 		storer: storer,
 		cacher: cacher,
+		events: events,
 	}
 }
 
@@ -40,23 +48,30 @@ func NewUserService(storer UserStorer, cacher UserCacher) HTTPUserHandler {
 // It updates the user in both the database and the cache
 // It returns the updated user in JSON format or an error if the update fails
 func (s *UserService) UpdateUserById(w http.ResponseWriter, r *http.Request) {
+	log := requestLogger(r.Context())
+
 	id, err := validateId(r.PathValue("id"))
 	if err != nil {
 		http.Error(w, fmt.Sprintf("Error: %s", err.Error()), http.StatusBadRequest)
-		fmt.Println("Error:", err.Error())
+		log.Error("invalid id", "error", err)
+		return
+	}
+
+	if !canMutateUser(r.Context(), id) {
+		http.Error(w, "Error: Forbidden", http.StatusForbidden)
 		return
 	}
 
 	var updates User
 	if err := json.NewDecoder(r.Body).Decode(&updates); err != nil {
 		http.Error(w, "Error: Invalid request body", http.StatusBadRequest)
-		fmt.Println("Error: Invalid request body")
+		log.Error("invalid request body", "error", err)
 		return
 	}
 
 	if updates.Name == "" {
 		http.Error(w, "Error: Name is required", http.StatusBadRequest)
-		fmt.Println("Error: Name is required")
+		log.Error("name is required")
 		return
 	}
 
@@ -64,22 +79,26 @@ func (s *UserService) UpdateUserById(w http.ResponseWriter, r *http.Request) {
 	if err := s.storer.UpdateUser(user, updates); err != nil {
 		if err == gorm.ErrRecordNotFound {
 			http.Error(w, "Error: User not found", http.StatusNotFound)
-			fmt.Printf("Error: User %d not found\n", id)
+			log.Error("user not found", "id", id)
 			return
 		}
 		http.Error(w, "Error: Failed to update user", http.StatusInternalServerError)
-		fmt.Println("Error: Failed to update user:", err)
+		log.Error("failed to update user", "id", id, "error", err)
 		return
 	}
 
-	if err := s.cacher.Set(strconv.Itoa(id), user); err != nil {
+	if err := s.cacher.Set(strconv.Itoa(id), user.Public()); err != nil {
 		http.Error(w, "Error: Failed to update user in cache", http.StatusInternalServerError)
 		return
 	}
 
+	if err := s.events.Publish(UserUpdatedOp, user.ID, user.Name); err != nil {
+		log.Error("failed to publish user.updated event", "id", id, "error", err)
+	}
+
 	w.Header().Set("Content-Type", "application/json")
 	w.WriteHeader(http.StatusOK)
-	json.NewEncoder(w).Encode(user)
+	json.NewEncoder(w).Encode(user.Public())
 }
 
 // DeleteUserById handles the deletion of a user by ID
@@ -87,10 +106,17 @@ func (s *UserService) UpdateUserById(w http.ResponseWriter, r *http.Request) {
 // It expects the ID to be passed in the request path as /users/{id}
 // It returns a 204 No Content status if successful, or an error if not
 func (s *UserService) DeleteUserById(w http.ResponseWriter, r *http.Request) {
+	log := requestLogger(r.Context())
+
 	id, err := validateId(r.PathValue("id"))
 	if err != nil {
 		http.Error(w, fmt.Sprintf("Error: %s", err.Error()), http.StatusBadRequest)
-		fmt.Println("Error:", err.Error())
+		log.Error("invalid id", "error", err)
+		return
+	}
+
+	if !canMutateUser(r.Context(), id) {
+		http.Error(w, "Error: Forbidden", http.StatusForbidden)
 		return
 	}
 
@@ -101,7 +127,7 @@ func (s *UserService) DeleteUserById(w http.ResponseWriter, r *http.Request) {
 			return
 		}
 		http.Error(w, "Error: Failed to delete user", http.StatusInternalServerError)
-		fmt.Println("Error: Failed to delete user:", err)
+		log.Error("failed to delete user", "id", id, "error", err)
 		return
 	}
 
@@ -111,6 +137,10 @@ func (s *UserService) DeleteUserById(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	if err := s.events.Publish(UserDeletedOp, uint(id), ""); err != nil {
+		log.Error("failed to publish user.deleted event", "id", id, "error", err)
+	}
+
 	w.WriteHeader(http.StatusNoContent)
 }
 
@@ -119,14 +149,22 @@ func (s *UserService) DeleteUserById(w http.ResponseWriter, r *http.Request) {
 // It expects the ID to be passed in the request path as /users/{id}
 // It returns the user in JSON format or an error if not found
 func (s *UserService) GetUserById(w http.ResponseWriter, r *http.Request) {
+	log := requestLogger(r.Context())
+
 	id, err := validateId(r.PathValue("id"))
 	if err != nil {
 		http.Error(w, fmt.Sprintf("Error: %s", err.Error()), http.StatusBadRequest)
-		fmt.Println("Error:", err.Error())
+		log.Error("invalid id", "error", err)
+		return
+	}
+	key := strconv.Itoa(id)
+
+	// Check cache first, including the negative cache for known-missing IDs
+	user, err := s.cacher.Get(key)
+	if err == ErrUserNotFound {
+		http.Error(w, "Error: User not found", http.StatusNotFound)
 		return
 	}
-	// Check cache first
-	user, err := s.cacher.Get(strconv.Itoa(id))
 	if err != nil {
 		http.Error(w, "Error: Failed to retrieve user from cache", http.StatusInternalServerError)
 		return
@@ -136,56 +174,33 @@ func (s *UserService) GetUserById(w http.ResponseWriter, r *http.Request) {
 		json.NewEncoder(w).Encode(user)
 		return
 	}
-	// If not found in cache, retrieve from database
-	user, err = s.storer.GetUser(id)
+	// If not found in cache, retrieve from database.
+	// Concurrent misses for the same ID collapse into a single DB read.
+	v, err, _ := s.getGroup.Do(key, func() (interface{}, error) {
+		return s.storer.GetUser(id)
+	})
 	if err == gorm.ErrRecordNotFound {
+		if err := s.cacher.SetNotFound(key); err != nil {
+			log.Error("failed to store negative cache entry", "id", id, "error", err)
+		}
 		http.Error(w, "Error: User not found", http.StatusNotFound)
-		fmt.Println("Error: User not found")
+		log.Error("user not found", "id", id)
 		return
 	}
 	if err != nil {
 		http.Error(w, "Error: Failed to retrieve user", http.StatusInternalServerError)
-		fmt.Println("Error: Failed to retrieve user")
-		return
-	}
-	if user == nil {
-		http.Error(w, "Error: User not found", http.StatusNotFound)
-		fmt.Println("Error: User not found")
+		log.Error("failed to retrieve user", "id", id, "error", err)
 		return
 	}
+	dbUser := v.(*User)
 	// Store in cache for future requests
-	if err := s.cacher.Set(strconv.Itoa(id), user); err != nil {
+	if err := s.cacher.Set(key, dbUser.Public()); err != nil {
 		http.Error(w, "Error: Failed to store user in cache", http.StatusInternalServerError)
 		return
 	}
 	w.Header().Set("Content-Type", "application/json")
 	w.WriteHeader(http.StatusOK)
-	json.NewEncoder(w).Encode(user)
-}
-
-// CreateUser handles the creation of a new user
-// It expects the user data in the request body as JSON
-// It validates the input and returns the created user in JSON format or an error if creation fails
-func (s *UserService) CreateUser(w http.ResponseWriter, r *http.Request) {
-	user := User{}
-	if err := json.NewDecoder(r.Body).Decode(&user); err != nil {
-		http.Error(w, "Error: Invalid request body", http.StatusBadRequest)
-		fmt.Println("Error: Invalid request body")
-		return
-	}
-	if user.Name == "" {
-		http.Error(w, "Error: Name is required", http.StatusBadRequest)
-		fmt.Println("Error: Name is required")
-		return
-	}
-	if err := s.storer.CreateUser(&user); err != nil {
-		http.Error(w, "Error: Failed to create user", http.StatusInternalServerError)
-		fmt.Println("Error: Failed to create user")
-		return
-	}
-	w.Header().Set("Content-Type", "application/json")
-	w.WriteHeader(http.StatusCreated)
-	json.NewEncoder(w).Encode(user)
+	json.NewEncoder(w).Encode(dbUser.Public())
 }
 
 // GetAllUsers handles the retrieval of all users
@@ -193,19 +208,41 @@ func (s *UserService) CreateUser(w http.ResponseWriter, r *http.Request) {
 // If no users are found, it returns a 204 No Content status
 // If an error occurs, it returns a 500 Internal Server Error status
 func (s *UserService) GetAllUsers(w http.ResponseWriter, r *http.Request) {
+	log := requestLogger(r.Context())
+
+	authedUser, ok := UserFromContext(r.Context())
+	if !ok || authedUser.Role != RoleHost {
+		http.Error(w, "Error: Forbidden", http.StatusForbidden)
+		return
+	}
+
 	users, err := s.storer.GetUsers()
 	if err != nil {
 		http.Error(w, "Error: Failed to retrieve users", http.StatusInternalServerError)
-		fmt.Println("Error: Failed to retrieve users")
+		log.Error("failed to retrieve users", "error", err)
 		return
 	}
 	w.Header().Set("Content-Type", "application/json")
 	if len(users) == 0 {
 		w.WriteHeader(http.StatusNoContent)
-		fmt.Println("No users found")
+		log.Info("no users found")
 		return
 	}
-	json.NewEncoder(w).Encode(users)
+	publicUsers := make([]*PublicUser, len(users))
+	for i := range users {
+		publicUsers[i] = users[i].Public()
+	}
+	json.NewEncoder(w).Encode(publicUsers)
+}
+
+// canMutateUser reports whether the authenticated user on ctx may mutate the
+// user identified by id: only the owning user or a host may.
+func canMutateUser(ctx context.Context, id int) bool {
+	authedUser, ok := UserFromContext(ctx)
+	if !ok {
+		return false
+	}
+	return authedUser.Role == RoleHost || authedUser.UserID == uint(id)
 }
 
 // validateId checks if the provided ID is valid
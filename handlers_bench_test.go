@@ -0,0 +1,71 @@
+package main
+
+import (
+	"net/http/httptest"
+	"strconv"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// countingStorer simulates PostgreSQLUserStorer's behavior for a single,
+// permanently-missing ID: every GetUser call pays a fixed delay (standing in
+// for a real DB round-trip) and returns gorm.ErrRecordNotFound, while
+// counting how many times the database was actually hit.
+type countingStorer struct {
+	delay   time.Duration
+	dbCalls int64
+}
+
+func (s *countingStorer) CreateUser(user *User) error { return nil }
+func (s *countingStorer) GetUsers() ([]User, error)   { return nil, nil }
+
+func (s *countingStorer) GetUser(id int) (*User, error) {
+	atomic.AddInt64(&s.dbCalls, 1)
+	time.Sleep(s.delay)
+	return nil, gorm.ErrRecordNotFound
+}
+
+func (s *countingStorer) GetUserByEmail(email string) (*User, error) { return nil, nil }
+func (s *countingStorer) UpdateUser(user *User, updates User) error  { return nil }
+func (s *countingStorer) DeleteUser(id int) error                    { return nil }
+
+// BenchmarkGetUserById_ColdMiss drives 1k concurrent requests for the same
+// never-existing ID through UserService.GetUserById, reusing one cacher and
+// storer across b.N iterations. The per-key singleflight.Group collapses
+// each iteration's 1000 concurrent misses into a single DB call, and the
+// negative cache then serves every later iteration straight from the cache
+// -- so db-calls/op, reported below, should stay near zero instead of
+// scaling with N*concurrency the way an unprotected lookup would.
+func BenchmarkGetUserById_ColdMiss(b *testing.B) {
+	const concurrency = 1000
+
+	storer := &countingStorer{delay: 5 * time.Millisecond}
+	cacher := newFakeCacher()
+	handler := NewUserService(storer, cacher, nil)
+
+	id := 999999
+	path := "/users/" + strconv.Itoa(id)
+
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		var wg sync.WaitGroup
+		wg.Add(concurrency)
+		for j := 0; j < concurrency; j++ {
+			go func() {
+				defer wg.Done()
+				req := httptest.NewRequest("GET", path, nil)
+				req.SetPathValue("id", strconv.Itoa(id))
+				rec := httptest.NewRecorder()
+				handler.GetUserById(rec, req)
+			}()
+		}
+		wg.Wait()
+	}
+	b.StopTimer()
+
+	b.ReportMetric(float64(atomic.LoadInt64(&storer.dbCalls))/float64(b.N), "db-calls/op")
+}
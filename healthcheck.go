@@ -0,0 +1,40 @@
+package main
+
+import (
+	"context"
+
+	"github.com/redis/go-redis/v9"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/health/grpc_health_v1"
+	"google.golang.org/grpc/status"
+	"gorm.io/gorm"
+)
+
+// healthServer implements grpc_health_v1.HealthServer, reporting SERVING
+// only while both Postgres and Redis are reachable.
+type healthServer struct {
+	grpc_health_v1.UnimplementedHealthServer
+	db    *gorm.DB
+	cache *redis.Client
+}
+
+// newHealthServer creates a new healthServer
+func newHealthServer(db *gorm.DB, cache *redis.Client) *healthServer {
+	return &healthServer{db: db, cache: cache}
+}
+
+// Check pings Postgres and Redis and reports the aggregate status
+func (h *healthServer) Check(ctx context.Context, req *grpc_health_v1.HealthCheckRequest) (*grpc_health_v1.HealthCheckResponse, error) {
+	if sqlDB, err := h.db.DB(); err != nil || sqlDB.PingContext(ctx) != nil {
+		return &grpc_health_v1.HealthCheckResponse{Status: grpc_health_v1.HealthCheckResponse_NOT_SERVING}, nil
+	}
+	if err := h.cache.Ping(ctx).Err(); err != nil {
+		return &grpc_health_v1.HealthCheckResponse{Status: grpc_health_v1.HealthCheckResponse_NOT_SERVING}, nil
+	}
+	return &grpc_health_v1.HealthCheckResponse{Status: grpc_health_v1.HealthCheckResponse_SERVING}, nil
+}
+
+// Watch is not supported; streaming health checks aren't needed by this service
+func (h *healthServer) Watch(req *grpc_health_v1.HealthCheckRequest, stream grpc_health_v1.Health_WatchServer) error {
+	return status.Error(codes.Unimplemented, "watch is not supported")
+}
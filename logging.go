@@ -0,0 +1,59 @@
+package main
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"log/slog"
+	"net/http"
+	"os"
+	"time"
+)
+
+// requestIDKey is the context key a request's ID is stored under
+type requestIDKey struct{}
+
+// newLogger builds the process-wide structured logger at the given level
+// ("debug", "info", "warn" or "error"; defaults to "info" if unrecognized)
+func newLogger(level string) *slog.Logger {
+	var lvl slog.Level
+	if err := lvl.UnmarshalText([]byte(level)); err != nil {
+		lvl = slog.LevelInfo
+	}
+	return slog.New(slog.NewTextHandler(os.Stdout, &slog.HandlerOptions{Level: lvl}))
+}
+
+// withRequestID assigns each request a short random ID, carries it on the
+// request context, and logs a line for the request once it completes
+func withRequestID(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		id := generateRequestID()
+		ctx := context.WithValue(r.Context(), requestIDKey{}, id)
+		start := time.Now()
+		next.ServeHTTP(w, r.WithContext(ctx))
+		slog.Info("handled request",
+			"request_id", id,
+			"method", r.Method,
+			"path", r.URL.Path,
+			"duration", time.Since(start),
+		)
+	})
+}
+
+// requestLogger returns the default logger annotated with the request ID
+// carried on ctx, if any
+func requestLogger(ctx context.Context) *slog.Logger {
+	if id, ok := ctx.Value(requestIDKey{}).(string); ok {
+		return slog.With("request_id", id)
+	}
+	return slog.Default()
+}
+
+// generateRequestID returns a short random hex identifier for a request
+func generateRequestID() string {
+	buf := make([]byte, 8)
+	if _, err := rand.Read(buf); err != nil {
+		return "unknown"
+	}
+	return hex.EncodeToString(buf)
+}
@@ -1,29 +1,31 @@
 package main
 
 import (
+	"context"
+	"errors"
 	"fmt"
+	"log/slog"
+	"net"
 	"net/http"
 	"os"
-
-	"context"
+	"os/signal"
+	"syscall"
 
 	"github.com/redis/go-redis/v9"
+	"golang.org/x/sync/errgroup"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/health/grpc_health_v1"
 	"gorm.io/driver/postgres"
 	"gorm.io/gorm"
+
+	userv1 "github.com/andreistefanciprian/go-rest-cache-pubsub/proto/user/v1"
 )
 
 // initDb initializes the database connection and migrates the User model
-func initDb() (*gorm.DB, error) {
-
-	dbHost := getEnv("DB_HOST", "localhost")
-	dbPort := getEnv("DB_PORT", "5432")
-	dbUser := getEnv("DB_USER", "postgres")
-	dbPassword := getEnv("DB_PASSWORD", "password")
-	dbName := getEnv("DB_NAME", "users")
-
+func initDb(cfg *Config) (*gorm.DB, error) {
 	// Initialize database connection
 	dsn := fmt.Sprintf("host=%s user=%s password=%s dbname=%s port=%s sslmode=disable TimeZone=Asia/Shanghai",
-		dbHost, dbUser, dbPassword, dbName, dbPort)
+		cfg.DBHost, cfg.DBUser, cfg.DBPassword, cfg.DBName, cfg.DBPort)
 	db, err := gorm.Open(postgres.Open(dsn), &gorm.Config{})
 	if err != nil {
 		return nil, fmt.Errorf("failed to connect to database: %w", err)
@@ -38,32 +40,31 @@ func initDb() (*gorm.DB, error) {
 }
 
 // initCache initializes the Redis cache connection
-func initCache() (*redis.Client, error) {
-
-	// Get configuration from environment variables with defaults
-	redisHost := getEnv("REDIS_HOST", "localhost")
-	redisPort := getEnv("REDIS_PORT", "6379")
-	redisPassword := getEnv("REDIS_PASSWORD", "redispassword")
-
+func initCache(cfg *Config) (*redis.Client, error) {
 	// Initialize Redis client
 	cache := redis.NewClient(&redis.Options{
-		Addr:     fmt.Sprintf("%s:%s", redisHost, redisPort),
-		Password: redisPassword,
+		Addr:     fmt.Sprintf("%s:%s", cfg.RedisHost, cfg.RedisPort),
+		Password: cfg.RedisPassword,
 		DB:       0,
 	})
 	ctx := context.Background()
 	pong, err := cache.Ping(ctx).Result()
-	fmt.Println("Redis ping:", pong, err)
+	slog.Info("redis ping", "pong", pong, "error", err)
 
 	return cache, nil
 }
 
-// getEnv gets an environment variable or returns a default value
-func getEnv(key, defaultValue string) string {
-	if value := os.Getenv(key); value != "" {
-		return value
+// instanceID returns a value identifying this process among other instances
+// sharing the same Redis, defaulting to hostname:pid when cfg.InstanceID is unset
+func instanceID(cfg *Config) string {
+	if cfg.InstanceID != "" {
+		return cfg.InstanceID
 	}
-	return defaultValue
+	hostname, err := os.Hostname()
+	if err != nil {
+		hostname = "unknown"
+	}
+	return fmt.Sprintf("%s:%d", hostname, os.Getpid())
 }
 
 // withNotFoundHandler wraps the HTTP handler to return a 404 Not Found error
@@ -73,7 +74,7 @@ func withNotFoundHandler(mux *http.ServeMux) http.Handler {
 		_, pattern := mux.Handler(r)
 		if pattern == "" {
 			http.Error(w, "Error: Route not found", http.StatusNotFound)
-			fmt.Println("Error: Route not found for", r.Method, r.URL.Path)
+			slog.Info("route not found", "method", r.Method, "path", r.URL.Path)
 			return
 		}
 		mux.ServeHTTP(w, r)
@@ -81,31 +82,131 @@ func withNotFoundHandler(mux *http.ServeMux) http.Handler {
 }
 
 func main() {
+	cfg, err := LoadConfig()
+	if err != nil {
+		fmt.Println("Error loading config:", err)
+		os.Exit(1)
+	}
+	slog.SetDefault(newLogger(cfg.LogLevel))
+
 	// Initialize database and cache
-	db, err := initDb()
+	db, err := initDb(cfg)
 	if err != nil {
-		fmt.Println("Error initializing database:", err)
-		return
+		slog.Error("failed to initialize database", "error", err)
+		os.Exit(1)
 	}
 	userStorer := NewPostgreSQLUserStorer(db)
 
 	// Initialize Redis cache
-	cache, err := initCache()
+	cache, err := initCache(cfg)
 	if err != nil {
-		fmt.Println("Error initializing Redis cache:", err)
-		return
+		slog.Error("failed to initialize redis cache", "error", err)
+		os.Exit(1)
 	}
-	userCacher := NewRedisUserCacher(cache)
+	userCacher := NewRedisUserCacher(cache, cfg.cacheOptions())
+
+	// Initialize the pub/sub event bus and start the invalidation subscriber
+	eventBus := NewUserEventBus(cache, instanceID(cfg))
+	subCtx, stopSub := context.WithCancel(context.Background())
+	defer stopSub()
+	go eventBus.Subscribe(subCtx, userCacher, userStorer)
 
-	handler := NewUserService(userStorer, userCacher)
+	handler := NewUserService(userStorer, userCacher, eventBus)
+	auth := NewAuthService(userStorer, cache, cfg.SessionTTL, userCacher, eventBus)
 
 	// Set up HTTP server and routes
-	fmt.Println("Server is starting on port 8080...")
 	mux := http.NewServeMux()
-	mux.HandleFunc("POST /users", handler.CreateUser)
+	mux.HandleFunc("POST /auth/signup", auth.SignUp)
+	mux.HandleFunc("POST /auth/signin", auth.SignIn)
 	mux.HandleFunc("GET /users", handler.GetAllUsers)
 	mux.HandleFunc("GET /users/{id}", handler.GetUserById)
 	mux.HandleFunc("PUT /users/{id}", handler.UpdateUserById)
 	mux.HandleFunc("DELETE /users/{id}", handler.DeleteUserById)
-	http.ListenAndServe(":8080", withNotFoundHandler(mux))
+
+	srv := &http.Server{
+		Addr:         cfg.HTTPAddr,
+		Handler:      withRequestID(auth.Middleware(withNotFoundHandler(mux))),
+		ReadTimeout:  cfg.ReadTimeout,
+		WriteTimeout: cfg.WriteTimeout,
+	}
+
+	// Set up the gRPC server, exposing the same UserService behind a
+	// separate, independently configurable listener
+	grpcServer := grpc.NewServer(grpc.UnaryInterceptor(unaryLoggingAuthInterceptor(auth)))
+	userv1.RegisterUserServiceServer(grpcServer, NewGRPCUserServer(userStorer, userCacher, eventBus))
+	grpc_health_v1.RegisterHealthServer(grpcServer, newHealthServer(db, cache))
+
+	grpcListener, err := net.Listen("tcp", cfg.GRPCAddr)
+	if err != nil {
+		slog.Error("failed to listen for grpc", "addr", cfg.GRPCAddr, "error", err)
+		os.Exit(1)
+	}
+
+	ctx, stop := signal.NotifyContext(context.Background(), syscall.SIGINT, syscall.SIGTERM)
+	defer stop()
+
+	g, gCtx := errgroup.WithContext(ctx)
+	g.Go(func() error {
+		slog.Info("http server is starting", "addr", cfg.HTTPAddr)
+		if err := srv.ListenAndServe(); err != nil && !errors.Is(err, http.ErrServerClosed) {
+			return fmt.Errorf("http server failed: %w", err)
+		}
+		return nil
+	})
+	g.Go(func() error {
+		slog.Info("grpc server is starting", "addr", cfg.GRPCAddr)
+		if err := grpcServer.Serve(grpcListener); err != nil {
+			return fmt.Errorf("grpc server failed: %w", err)
+		}
+		return nil
+	})
+
+	<-gCtx.Done()
+	slog.Info("shutdown signal received, draining in-flight requests", "timeout", cfg.ShutdownTimeout)
+	stopSub()
+
+	shutdownCtx, cancel := context.WithTimeout(context.Background(), cfg.ShutdownTimeout)
+	defer cancel()
+
+	grpcStopped := make(chan struct{})
+	go func() {
+		grpcServer.GracefulStop()
+		close(grpcStopped)
+	}()
+
+	httpShutdownErr := srv.Shutdown(shutdownCtx)
+
+	select {
+	case <-grpcStopped:
+	case <-shutdownCtx.Done():
+		grpcServer.Stop()
+	}
+
+	if httpShutdownErr != nil {
+		slog.Error("http shutdown timed out, exiting", "error", httpShutdownErr)
+		closeResources(db, cache)
+		os.Exit(1)
+	}
+
+	if err := g.Wait(); err != nil {
+		slog.Error("server failed", "error", err)
+		closeResources(db, cache)
+		os.Exit(1)
+	}
+
+	closeResources(db, cache)
+	slog.Info("shutdown complete")
+}
+
+// closeResources closes the database connection pool and Redis client
+func closeResources(db *gorm.DB, cache *redis.Client) {
+	if sqlDB, err := db.DB(); err != nil {
+		slog.Error("failed to get underlying sql.DB", "error", err)
+	} else if err := sqlDB.Close(); err != nil {
+		slog.Error("failed to close database pool", "error", err)
+	}
+
+	if err := cache.Close(); err != nil {
+		slog.Error("failed to close redis client", "error", err)
+	}
 }
@@ -0,0 +1,34 @@
+package userv1
+
+import (
+	"encoding/json"
+
+	"google.golang.org/grpc/encoding"
+)
+
+// jsonCodec marshals gRPC messages as JSON instead of the protobuf wire
+// format. It registers itself under the name "proto", which is the codec
+// grpc-go selects by default when a call sets no content-subtype -- so it
+// replaces the standard codec for this package's plain Go structs, none of
+// which implement proto.Message. The registration is process-wide: every
+// other message sent or received with the default codec, including the
+// grpc.health.v1 messages, is encoded as JSON as well. Once protoc-gen-go and
+// protoc-gen-go-grpc are available to regenerate real protobuf types (see
+// `make proto`), this file and its registration should be removed.
+type jsonCodec struct{}
+
+func (jsonCodec) Marshal(v interface{}) ([]byte, error) {
+	return json.Marshal(v)
+}
+
+func (jsonCodec) Unmarshal(data []byte, v interface{}) error {
+	return json.Unmarshal(data, v)
+}
+
+func (jsonCodec) Name() string {
+	return "proto"
+}
+
+func init() {
+	encoding.RegisterCodec(jsonCodec{})
+}